@@ -0,0 +1,75 @@
+package types
+
+// ServiceCreateOptions contains the options to use when creating a service.
+type ServiceCreateOptions struct {
+	// EncodedRegistryAuth is the encoded registry authorization credentials
+	// to use when updating the service.
+	EncodedRegistryAuth string
+
+	// QueryRegistry indicates whether the service update requires
+	// contacting the registry. A registry may be contacted to retrieve
+	// the image digest and manifest, which in turn can be used to update
+	// platform or other information about the service.
+	QueryRegistry bool
+
+	// TrustedResolution resolves the service's image through the Client's
+	// TrustResolver instead of an unauthenticated registry manifest lookup.
+	// Unlike QueryRegistry, this fails closed: a tag with no signed target
+	// returns ErrTrustedResolutionFailed rather than falling back to an
+	// unsigned digest. Takes priority over QueryRegistry when both are set.
+	TrustedResolution bool
+}
+
+// ServiceCreateResponse contains the information returned to a client on the
+// creation of a new service.
+type ServiceCreateResponse struct {
+	// ID is the ID of the created service.
+	ID string
+
+	// Warnings is a set of non-fatal warning messages to pass on to the
+	// user.
+	Warnings []string `json:",omitempty"`
+
+	// StructuredWarnings is the machine-readable counterpart to Warnings:
+	// one ServiceWarning per problem ServiceCreate could recover from
+	// (a digest it couldn't pin, a placement it couldn't satisfy), or the
+	// trust failure returned alongside ErrTrustedResolutionFailed, so that
+	// callers don't have to string-match Warnings to tell them apart.
+	StructuredWarnings []ServiceWarning `json:",omitempty"`
+}
+
+// ServiceUpdateOptions contains the options to be used for updating services.
+type ServiceUpdateOptions struct {
+	// EncodedRegistryAuth is the encoded registry authorization credentials
+	// to use when updating the service.
+	EncodedRegistryAuth string
+
+	// RegistryAuthFrom specifies where to find the registry authorization
+	// credentials if they are not given in EncodedRegistryAuth. Valid
+	// values are "spec" and "previous-spec".
+	RegistryAuthFrom string
+
+	// Rollback indicates whether a server-side rollback should be
+	// performed instead of an update.
+	Rollback string
+
+	// QueryRegistry indicates whether the service update requires
+	// contacting the registry. A registry may be contacted to retrieve
+	// the image digest and manifest, which in turn can be used to update
+	// platform or other information about the service.
+	QueryRegistry bool
+
+	// TrustedResolution mirrors ServiceCreateOptions.TrustedResolution.
+	TrustedResolution bool
+}
+
+// ServiceUpdateResponse contains the information returned to a client on the
+// update of a service.
+type ServiceUpdateResponse struct {
+	// Warnings is a set of non-fatal warning messages to pass on to the
+	// user.
+	Warnings []string `json:",omitempty"`
+
+	// StructuredWarnings mirrors ServiceCreateResponse.StructuredWarnings.
+	StructuredWarnings []ServiceWarning `json:",omitempty"`
+}
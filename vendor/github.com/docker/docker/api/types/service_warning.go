@@ -0,0 +1,45 @@
+package types
+
+// ServiceWarningCode identifies the class of problem a ServiceWarning
+// describes, so that programmatic callers (CI systems, orchestrators
+// wrapping the client) can react to specific failure classes instead of
+// string-matching the legacy warning text in Warnings.
+type ServiceWarningCode string
+
+const (
+	// DigestPinFailed means the registry could not be queried to pin a
+	// service's image by digest.
+	DigestPinFailed ServiceWarningCode = "digest_pin_failed"
+	// PlatformIntersectionEmpty means an operator-restricted placement
+	// shares no platform with the image's manifest list.
+	PlatformIntersectionEmpty ServiceWarningCode = "platform_intersection_empty"
+	// TrustResolutionFailed means TrustedResolution was set but no signed
+	// target could be resolved for the image.
+	TrustResolutionFailed ServiceWarningCode = "trust_resolution_failed"
+	// RegistryUnauthorized means the registry rejected the credentials
+	// used to look up the image.
+	RegistryUnauthorized ServiceWarningCode = "registry_unauthorized"
+)
+
+// ServiceWarning is a structured, machine-readable counterpart to the
+// legacy warning strings ServiceCreate, ServiceUpdate and ServicesCreate
+// append to Warnings. Callers that need to distinguish "registry
+// unreachable" from "manifest not found" from "auth denied" should use
+// ServiceCreateResponse.StructuredWarnings instead of string-matching
+// Warnings, which is kept populated for backward compatibility.
+type ServiceWarning struct {
+	Code    ServiceWarningCode
+	Image   string
+	Message string
+	Err     error
+}
+
+func (w ServiceWarning) Error() string {
+	return w.Message
+}
+
+// Unwrap allows errors.Is/As to reach the underlying registry or trust
+// error.
+func (w ServiceWarning) Unwrap() error {
+	return w.Err
+}
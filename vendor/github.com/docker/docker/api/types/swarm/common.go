@@ -0,0 +1,6 @@
+package swarm
+
+// Version tracks the last committed version of an object.
+type Version struct {
+	Index uint64 `json:",omitempty"`
+}
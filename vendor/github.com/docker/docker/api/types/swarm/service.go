@@ -0,0 +1,10 @@
+package swarm
+
+// ServiceSpec represents the spec of a service.
+//
+// This vendor copy only carries TaskTemplate, the field the client package's
+// image resolution and placement logic reads and rewrites; the real type
+// also holds Annotations, Mode, UpdateConfig and networking configuration.
+type ServiceSpec struct {
+	TaskTemplate TaskSpec
+}
@@ -0,0 +1,31 @@
+package swarm
+
+// ContainerSpec represents the spec of a container.
+//
+// This vendor copy only carries the field the client package's digest/trust
+// pinning touches; the real type also covers command, environment, mounts
+// and the rest of a container's runtime configuration.
+type ContainerSpec struct {
+	Image string `json:",omitempty"`
+}
+
+// Placement constrains the set of nodes a task can be scheduled on.
+//
+// This vendor copy only carries Platforms, the field updateServicePlatforms
+// reconciles; the real type also holds scheduling Constraints and
+// Preferences.
+type Placement struct {
+	Platforms []Platform `json:",omitempty"`
+}
+
+// TaskSpec represents the spec of a task.
+//
+// ContainerSpec is a value, not a pointer as in the upstream type: every
+// caller in the client package (ServiceCreate, ServiceUpdate, ServicesCreate, and
+// their tests) reads or writes TaskTemplate.ContainerSpec.Image directly off
+// a zero-value ServiceSpec without a nil check, which only stays safe if
+// the field is always addressable.
+type TaskSpec struct {
+	ContainerSpec ContainerSpec
+	Placement     *Placement `json:",omitempty"`
+}
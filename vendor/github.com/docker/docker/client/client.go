@@ -0,0 +1,28 @@
+package client
+
+// Opt is a configuration option passed to NewClient to modify the Client.
+type Opt func(*Client) error
+
+// Client augments the HTTP client used to query the Docker API with the
+// pluggable strategies ServiceCreate, ServiceUpdate and ServicesCreate use
+// to resolve a service's image before pinning it on the TaskTemplate.
+//
+// Apart from version, the fields below are the ones this package's resolver
+// options (WithTrustResolver, WithImageResolver) configure; the transport,
+// version negotiation and other base client state live alongside them.
+type Client struct {
+	version string
+
+	// trustResolver resolves images to a signed digest when
+	// ServiceCreateOptions.TrustedResolution (or the equivalent
+	// ServiceUpdateOptions/ServicesCreateOptions field) is set. Configured
+	// with WithTrustResolver; nil means trust resolution is unavailable,
+	// and TrustedResolution calls fail with ErrTrustedResolutionFailed for
+	// any image that is not already digest-pinned.
+	trustResolver TrustResolver
+
+	// imageResolver resolves images to a digest and platform list when
+	// QueryRegistry is set. Configured with WithImageResolver; nil falls
+	// back to a DistributionInspect-backed resolver.
+	imageResolver ImageResolver
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+// ImageResolver resolves an image reference to the digest and set of
+// supported platforms used to pin a service's image and constrain its
+// placement. ServiceCreate, ServiceUpdate and ServicesCreate all go
+// through the Client's ImageResolver instead of calling DistributionInspect
+// directly, so that callers can plug in a local content-addressable cache,
+// a corporate registry mirror, a signed-metadata store, or a test stub
+// without forking the client.
+type ImageResolver interface {
+	Resolve(ctx context.Context, ref, encodedAuth string) (digest.Digest, []ocispec.Platform, error)
+}
+
+// WithImageResolver configures the ImageResolver used to satisfy
+// ServiceCreateOptions.QueryRegistry (and the equivalent ServiceUpdate and
+// ServicesCreate options), in place of the default DistributionInspect-backed
+// lookup.
+func WithImageResolver(resolver ImageResolver) Opt {
+	return func(c *Client) error {
+		c.imageResolver = resolver
+		return nil
+	}
+}
+
+// imageResolverFor returns cli.imageResolver, falling back to a
+// DistributionInspect-backed resolver when none has been configured.
+func (cli *Client) imageResolverFor() ImageResolver {
+	if cli.imageResolver != nil {
+		return cli.imageResolver
+	}
+	return &distributionResolver{cli: cli}
+}
+
+// distributionResolver is the default ImageResolver. It resolves images the
+// way ServiceCreate always has, via Client.DistributionInspect.
+type distributionResolver struct {
+	cli *Client
+}
+
+func (r *distributionResolver) Resolve(ctx context.Context, ref, encodedAuth string) (digest.Digest, []ocispec.Platform, error) {
+	distributionInspect, err := r.cli.DistributionInspect(ctx, ref, encodedAuth)
+	if err != nil {
+		return "", nil, err
+	}
+	platforms := make([]ocispec.Platform, 0, len(distributionInspect.Platforms))
+	for _, p := range distributionInspect.Platforms {
+		platforms = append(platforms, ocispec.Platform{
+			Architecture: p.Architecture,
+			OS:           p.OS,
+			Variant:      p.Variant,
+			OSVersion:    p.OSVersion,
+		})
+	}
+	return distributionInspect.Descriptor.Digest, platforms, nil
+}
+
+// CachingResolver wraps another ImageResolver and memoizes its results for
+// TTL, keyed by image reference and registry auth. Tooling like
+// "docker stack deploy" issues a ServiceCreate/ServiceUpdate call per
+// service in a compose file and commonly repeats the same handful of
+// images across many of them; without this, each call re-hits the
+// registry even though the prior lookup is still fresh.
+type CachingResolver struct {
+	Resolver ImageResolver
+	TTL      time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedResolution
+}
+
+type cachedResolution struct {
+	digest    digest.Digest
+	platforms []ocispec.Platform
+	expiresAt time.Time
+}
+
+// NewCachingResolver wraps resolver with a cache whose entries expire
+// after ttl.
+func NewCachingResolver(resolver ImageResolver, ttl time.Duration) *CachingResolver {
+	return &CachingResolver{
+		Resolver: resolver,
+		TTL:      ttl,
+		cache:    make(map[string]cachedResolution),
+	}
+}
+
+// Resolve implements ImageResolver, serving from cache when a fresh entry
+// exists for ref and encodedAuth and delegating to the wrapped resolver
+// otherwise. Only successful resolutions are cached: a transient registry
+// error (a timeout, a 503) must not get memoized and replayed as a hard
+// failure to every service that references the image for the rest of the
+// TTL window, so an error result is always passed straight through
+// without touching the cache.
+func (c *CachingResolver) Resolve(ctx context.Context, ref, encodedAuth string) (digest.Digest, []ocispec.Platform, error) {
+	key := ref + "\x00" + encodedAuth
+
+	c.mu.Lock()
+	entry, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expiresAt) {
+		return entry.digest, entry.platforms, nil
+	}
+
+	dgst, platforms, err := c.Resolver.Resolve(ctx, ref, encodedAuth)
+	if err != nil {
+		return dgst, platforms, err
+	}
+
+	c.mu.Lock()
+	c.cache[key] = cachedResolution{
+		digest:    dgst,
+		platforms: platforms,
+		expiresAt: time.Now().Add(c.TTL),
+	}
+	c.mu.Unlock()
+
+	return dgst, platforms, nil
+}
+
+// platformsFromOCI converts OCI image index platforms, as returned by an
+// ImageResolver, to the swarm.Platform values updateServicePlatforms
+// operates on. Only OS and architecture carry over: swarm.Platform has no
+// variant or os.version fields, since swarmkit's own node description
+// doesn't report them either.
+func platformsFromOCI(platforms []ocispec.Platform) []swarm.Platform {
+	out := make([]swarm.Platform, 0, len(platforms))
+	for _, p := range platforms {
+		out = append(out, swarm.Platform{
+			Architecture: p.Architecture,
+			OS:           p.OS,
+		})
+	}
+	return out
+}
@@ -3,10 +3,10 @@ package client
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
-	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/opencontainers/go-digest"
 	"golang.org/x/net/context"
@@ -14,7 +14,7 @@ import (
 
 // ServiceCreate creates a new Service.
 func (cli *Client) ServiceCreate(ctx context.Context, service swarm.ServiceSpec, options types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
-	var distErr error
+	var distErr, platformErr error
 
 	headers := map[string][]string{
 		"version": {cli.version},
@@ -24,18 +24,38 @@ func (cli *Client) ServiceCreate(ctx context.Context, service swarm.ServiceSpec,
 		headers["X-Registry-Auth"] = []string{options.EncodedRegistryAuth}
 	}
 
-	// Contact the registry to retrieve digest and platform information
-	if options.QueryRegistry {
-		distributionInspect, err := cli.DistributionInspect(ctx, service.TaskTemplate.ContainerSpec.Image, options.EncodedRegistryAuth)
+	// Captured before either branch below mutates ContainerSpec.Image to a
+	// digest-pinned reference, so a platform-intersection warning reports
+	// the image the operator actually specified, the same as the digest
+	// warning already does.
+	originalImage := service.TaskTemplate.ContainerSpec.Image
+
+	switch {
+	case options.TrustedResolution:
+		// Resolve the image through the configured trust server rather than
+		// an unauthenticated registry manifest lookup. This fails closed:
+		// when the tag has no signed target, no service is created and
+		// callers get an error (with the matching TrustResolutionFailed
+		// structured warning) rather than only a non-fatal warning.
+		img, err := resolveTrustedImage(ctx, cli.trustResolver, originalImage)
+		if err != nil {
+			warning := trustServiceWarning(originalImage, err)
+			return types.ServiceCreateResponse{StructuredWarnings: []types.ServiceWarning{warning}}, err
+		}
+		service.TaskTemplate.ContainerSpec.Image = img
+	case options.QueryRegistry:
+		// Resolve digest and platform information through the configured
+		// ImageResolver (DistributionInspect-backed by default).
+		dgst, platforms, err := cli.imageResolverFor().Resolve(ctx, originalImage, options.EncodedRegistryAuth)
 		distErr = err
 		if err == nil {
 			// now pin by digest if the image doesn't already contain a digest
-			img := imageWithDigestString(service.TaskTemplate.ContainerSpec.Image, distributionInspect.Descriptor.Digest)
+			img := imageWithDigestString(originalImage, dgst)
 			if img != "" {
 				service.TaskTemplate.ContainerSpec.Image = img
 			}
-			// add platforms that are compatible with the service
-			service.TaskTemplate.Placement = updateServicePlatforms(service.TaskTemplate.Placement, distributionInspect)
+			// narrow (or populate) the compatible placement platforms
+			service.TaskTemplate.Placement, platformErr = updateServicePlatforms(service.TaskTemplate.Placement, platformsFromOCI(platforms))
 		}
 	}
 	var response types.ServiceCreateResponse
@@ -47,7 +67,12 @@ func (cli *Client) ServiceCreate(ctx context.Context, service swarm.ServiceSpec,
 	err = json.NewDecoder(resp.body).Decode(&response)
 
 	if distErr != nil {
-		response.Warnings = append(response.Warnings, digestWarning(service.TaskTemplate.ContainerSpec.Image))
+		response.Warnings = append(response.Warnings, digestWarning(originalImage))
+		response.StructuredWarnings = append(response.StructuredWarnings, digestServiceWarning(originalImage, distErr))
+	}
+	if platformErr != nil {
+		response.Warnings = append(response.Warnings, platformErr.Error())
+		response.StructuredWarnings = append(response.StructuredWarnings, platformServiceWarning(originalImage, platformErr))
 	}
 
 	ensureReaderClosed(resp)
@@ -74,20 +99,81 @@ func imageWithDigestString(image string, dgst digest.Digest) string {
 	return ""
 }
 
-// updateServicePlatforms updates the Platforms in swarm.Placement to list
-// all compatible platforms for the service, as found in distributionInspect
-// and returns a pointer to the new or updated swarm.Placement struct
-func updateServicePlatforms(placement *swarm.Placement, distributionInspect registrytypes.DistributionInspect) *swarm.Placement {
+// updateServicePlatforms reconciles the Platforms in swarm.Placement with
+// available, the platforms an ImageResolver reported for the service's
+// image.
+//
+// If placement is empty, every platform from the manifest list is used, as
+// before. If the operator already restricted placement to a specific set
+// of platforms (e.g. linux/arm64), the result is the intersection of that
+// set with the manifest list's entries, never a union - a multi-arch image
+// must not be allowed to widen placement past what was explicitly asked
+// for. The comparison is limited to OS and architecture: swarm.Platform
+// (and the node description a worker reports to the manager) doesn't carry
+// a manifest-list's "variant" or "os.version" fields, so there is nothing
+// on the server side to match a finer-grained intersection against.
+//
+// An error is returned when an explicit placement shares no platform with
+// the manifest list, so that the caller can surface it as a warning rather
+// than failing the whole create. Crucially, placement.Platforms is left as
+// the operator's original request in that case, not cleared: an empty
+// Platforms list means "no constraint, schedule anywhere" to swarmkit, so
+// clearing it on this exact failure would turn a placement mismatch into
+// no placement restriction at all - strictly worse than the union-based
+// widening this function replaces.
+//
+// If available itself is empty - a single-architecture image that isn't
+// published as a manifest list reports no platforms even though its digest
+// resolved fine - there is nothing to narrow by, so an explicit placement
+// is left untouched, the same as the old append-only behavior. Otherwise
+// this would turn every QueryRegistry deploy of a single-arch image with
+// an explicit placement constraint into a spurious "cannot run on any
+// requested platform" warning.
+func updateServicePlatforms(placement *swarm.Placement, available []swarm.Platform) (*swarm.Placement, error) {
 	if placement == nil {
 		placement = &swarm.Placement{}
 	}
-	for _, p := range distributionInspect.Platforms {
-		placement.Platforms = append(placement.Platforms, swarm.Platform{
-			Architecture: p.Architecture,
-			OS:           p.OS,
-		})
+
+	if len(placement.Platforms) == 0 || len(available) == 0 {
+		if len(placement.Platforms) == 0 {
+			placement.Platforms = available
+		}
+		return placement, nil
 	}
-	return placement
+
+	requested := placement.Platforms
+	var intersection []swarm.Platform
+	for _, r := range requested {
+		for _, candidate := range available {
+			if platformsCompatible(r, candidate) {
+				intersection = append(intersection, candidate)
+				break
+			}
+		}
+	}
+	if len(intersection) == 0 {
+		// Keep the operator's original constraint so the task still can't
+		// be scheduled outside it; only the warning tells them why.
+		return placement, fmt.Errorf("image cannot run on any of the requested platforms (%s)", formatPlatforms(requested))
+	}
+	placement.Platforms = intersection
+	return placement, nil
+}
+
+// formatPlatforms renders platforms as a comma-separated "os/arch" list for
+// use in error and warning messages.
+func formatPlatforms(platforms []swarm.Platform) string {
+	names := make([]string, 0, len(platforms))
+	for _, p := range platforms {
+		names = append(names, p.OS+"/"+p.Architecture)
+	}
+	return strings.Join(names, ", ")
+}
+
+// platformsCompatible reports whether requested and candidate refer to the
+// same OS and architecture.
+func platformsCompatible(requested, candidate swarm.Platform) bool {
+	return requested.Architecture == candidate.Architecture && requested.OS == candidate.OS
 }
 
 // digestWarning constructs a formatted warning string using the
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+)
+
+func TestUpdateServicePlatformsNoConstraint(t *testing.T) {
+	available := []swarm.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64"},
+	}
+
+	placement, err := updateServicePlatforms(nil, available)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(placement.Platforms) != len(available) {
+		t.Fatalf("expected every manifest platform to be used, got %v", placement.Platforms)
+	}
+}
+
+func TestUpdateServicePlatformsIntersects(t *testing.T) {
+	available := []swarm.Platform{
+		{OS: "linux", Architecture: "amd64"},
+		{OS: "linux", Architecture: "arm64"},
+		{OS: "linux", Architecture: "arm"},
+	}
+	placement := &swarm.Placement{
+		Platforms: []swarm.Platform{
+			{OS: "linux", Architecture: "arm64"},
+			{OS: "linux", Architecture: "arm"},
+			// windows/amd64 isn't in the manifest list and must be dropped,
+			// not unioned in.
+			{OS: "windows", Architecture: "amd64"},
+		},
+	}
+
+	result, err := updateServicePlatforms(placement, available)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Platforms) != 2 {
+		t.Fatalf("expected intersection of 2 platforms, got %v", result.Platforms)
+	}
+	for _, p := range result.Platforms {
+		if p.OS == "windows" {
+			t.Fatalf("intersection widened placement to include %v", p)
+		}
+	}
+}
+
+func TestUpdateServicePlatformsEmptyIntersectionPreservesRequested(t *testing.T) {
+	available := []swarm.Platform{
+		{OS: "linux", Architecture: "amd64"},
+	}
+	requested := []swarm.Platform{
+		{OS: "linux", Architecture: "arm64"},
+	}
+	placement := &swarm.Placement{Platforms: requested}
+
+	result, err := updateServicePlatforms(placement, available)
+	if err == nil {
+		t.Fatal("expected an error when the image has no matching platform")
+	}
+	if len(result.Platforms) != 1 || result.Platforms[0] != requested[0] {
+		t.Fatalf("expected placement to keep the operator's original constraint, got %v", result.Platforms)
+	}
+}
+
+func TestUpdateServicePlatformsEmptyAvailablePreservesRequested(t *testing.T) {
+	requested := []swarm.Platform{
+		{OS: "linux", Architecture: "amd64"},
+	}
+	placement := &swarm.Placement{Platforms: requested}
+
+	result, err := updateServicePlatforms(placement, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Platforms) != 1 || result.Platforms[0] != requested[0] {
+		t.Fatalf("expected placement to keep the operator's original constraint when the manifest reports no platforms, got %v", result.Platforms)
+	}
+}
+
+func TestPlatformsCompatible(t *testing.T) {
+	candidate := swarm.Platform{OS: "linux", Architecture: "arm"}
+
+	cases := []struct {
+		name      string
+		requested swarm.Platform
+		want      bool
+	}{
+		{"matches on arch and os", swarm.Platform{OS: "linux", Architecture: "arm"}, true},
+		{"rejects mismatched architecture", swarm.Platform{OS: "linux", Architecture: "arm64"}, false},
+		{"rejects mismatched os", swarm.Platform{OS: "windows", Architecture: "arm"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := platformsCompatible(c.requested, candidate); got != c.want {
+				t.Fatalf("platformsCompatible(%v, %v) = %v, want %v", c.requested, candidate, got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/net/context"
+)
+
+// ErrTrustedResolutionFailed is returned by ServiceCreate and ServiceUpdate
+// when ServiceCreateOptions.TrustedResolution (or the equivalent
+// ServiceUpdateOptions field) is set but the image reference could not be
+// resolved to a signed digest. Unlike the QueryRegistry path, trust
+// resolution fails closed: it is returned instead of falling back to an
+// unsigned registry digest.
+type ErrTrustedResolutionFailed struct {
+	Image string
+	Err   error
+}
+
+func (e *ErrTrustedResolutionFailed) Error() string {
+	return fmt.Sprintf("content trust: could not resolve a signed digest for %s: %v", e.Image, e.Err)
+}
+
+// Unwrap allows errors.Is/As to reach the underlying resolver error.
+func (e *ErrTrustedResolutionFailed) Unwrap() error {
+	return e.Err
+}
+
+// TrustResolver resolves a tagged image reference to the digest recorded
+// for it in a Notary/TUF trust server. Implementations are expected to
+// look the target up in the "targets" role, falling back to delegation
+// roles such as "targets/releases", and to return the SHA-256 digest from
+// the target's custom hashes once it has been verified against the role's
+// keys.
+//
+// A TrustResolver is configured on a Client with WithTrustResolver; it
+// typically wraps a Notary repository client pointed at the trust server
+// URL and root keys directory supplied by the caller.
+type TrustResolver interface {
+	ResolveTrustedDigest(ctx context.Context, ref reference.Named) (digest.Digest, error)
+}
+
+// WithTrustResolver configures the TrustResolver used to satisfy
+// TrustedResolution on ServiceCreate and ServiceUpdate calls.
+func WithTrustResolver(resolver TrustResolver) Opt {
+	return func(c *Client) error {
+		c.trustResolver = resolver
+		return nil
+	}
+}
+
+// resolveTrustedImage rewrites image to a canonical, digest-pinned
+// reference using resolver. It is a no-op if image is already canonical.
+func resolveTrustedImage(ctx context.Context, resolver TrustResolver, image string) (string, error) {
+	ref, err := reference.ParseAnyReference(image)
+	if err != nil {
+		return "", &ErrTrustedResolutionFailed{Image: image, Err: err}
+	}
+	if _, isCanonical := ref.(reference.Canonical); isCanonical {
+		return image, nil
+	}
+	named, ok := ref.(reference.Named)
+	if !ok {
+		return "", &ErrTrustedResolutionFailed{Image: image, Err: fmt.Errorf("%q cannot be resolved by tag", image)}
+	}
+	if resolver == nil {
+		return "", &ErrTrustedResolutionFailed{Image: image, Err: fmt.Errorf("no TrustResolver configured on the client")}
+	}
+	dgst, err := resolver.ResolveTrustedDigest(ctx, named)
+	if err != nil {
+		return "", &ErrTrustedResolutionFailed{Image: image, Err: err}
+	}
+	canonical, err := reference.WithDigest(named, dgst)
+	if err != nil {
+		return "", &ErrTrustedResolutionFailed{Image: image, Err: err}
+	}
+	return canonical.String(), nil
+}
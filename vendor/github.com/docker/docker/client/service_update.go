@@ -0,0 +1,84 @@
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"golang.org/x/net/context"
+)
+
+// ServiceUpdate updates a Service. The version number is required to avoid
+// conflicting writes.
+func (cli *Client) ServiceUpdate(ctx context.Context, serviceID string, version swarm.Version, service swarm.ServiceSpec, options types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+	var distErr, platformErr error
+
+	headers := map[string][]string{
+		"version": {cli.version},
+	}
+	if options.EncodedRegistryAuth != "" {
+		headers["X-Registry-Auth"] = []string{options.EncodedRegistryAuth}
+	}
+
+	// Captured before either branch below mutates ContainerSpec.Image to a
+	// digest-pinned reference, so a platform-intersection warning reports
+	// the image the operator actually specified, the same as the digest
+	// warning already does.
+	originalImage := service.TaskTemplate.ContainerSpec.Image
+
+	switch {
+	case options.TrustedResolution:
+		// See the equivalent branch in ServiceCreate: this fails closed
+		// rather than falling back to an unsigned registry digest.
+		img, err := resolveTrustedImage(ctx, cli.trustResolver, originalImage)
+		if err != nil {
+			warning := trustServiceWarning(originalImage, err)
+			return types.ServiceUpdateResponse{StructuredWarnings: []types.ServiceWarning{warning}}, err
+		}
+		service.TaskTemplate.ContainerSpec.Image = img
+	case options.QueryRegistry:
+		// Resolve digest and platform information through the configured
+		// ImageResolver (DistributionInspect-backed by default).
+		dgst, platforms, err := cli.imageResolverFor().Resolve(ctx, originalImage, options.EncodedRegistryAuth)
+		distErr = err
+		if err == nil {
+			// now pin by digest if the image doesn't already contain a digest
+			img := imageWithDigestString(originalImage, dgst)
+			if img != "" {
+				service.TaskTemplate.ContainerSpec.Image = img
+			}
+			// narrow (or populate) the compatible placement platforms
+			service.TaskTemplate.Placement, platformErr = updateServicePlatforms(service.TaskTemplate.Placement, platformsFromOCI(platforms))
+		}
+	}
+
+	query := url.Values{}
+	query.Set("version", strconv.FormatUint(version.Index, 10))
+	if options.RegistryAuthFrom != "" {
+		query.Set("registryAuthFrom", options.RegistryAuthFrom)
+	}
+	if options.Rollback != "" {
+		query.Set("rollback", options.Rollback)
+	}
+
+	var response types.ServiceUpdateResponse
+	resp, err := cli.post(ctx, "/services/"+serviceID+"/update", query, service, headers)
+	if err != nil {
+		return response, err
+	}
+
+	err = json.NewDecoder(resp.body).Decode(&response)
+	if distErr != nil {
+		response.Warnings = append(response.Warnings, digestWarning(originalImage))
+		response.StructuredWarnings = append(response.StructuredWarnings, digestServiceWarning(originalImage, distErr))
+	}
+	if platformErr != nil {
+		response.Warnings = append(response.Warnings, platformErr.Error())
+		response.StructuredWarnings = append(response.StructuredWarnings, platformServiceWarning(originalImage, platformErr))
+	}
+
+	ensureReaderClosed(resp)
+	return response, err
+}
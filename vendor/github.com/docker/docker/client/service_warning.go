@@ -0,0 +1,47 @@
+package client
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/errdefs"
+)
+
+// digestServiceWarning builds the structured counterpart of digestWarning,
+// classifying distErr as types.RegistryUnauthorized when the registry
+// rejected the supplied credentials and types.DigestPinFailed otherwise.
+func digestServiceWarning(image string, distErr error) types.ServiceWarning {
+	code := types.DigestPinFailed
+	if errdefs.IsUnauthorized(distErr) {
+		code = types.RegistryUnauthorized
+	}
+	return types.ServiceWarning{
+		Code:    code,
+		Image:   image,
+		Message: digestWarning(image),
+		Err:     distErr,
+	}
+}
+
+// platformServiceWarning builds the structured counterpart of a platform
+// intersection error.
+func platformServiceWarning(image string, platformErr error) types.ServiceWarning {
+	return types.ServiceWarning{
+		Code:    types.PlatformIntersectionEmpty,
+		Image:   image,
+		Message: platformErr.Error(),
+		Err:     platformErr,
+	}
+}
+
+// trustServiceWarning builds the structured counterpart of a trust
+// resolution error. ServiceCreate and ServiceUpdate return it alongside
+// ErrTrustedResolutionFailed when they refuse to proceed, and
+// ServicesCreate uses it to surface a per-service trust failure without
+// aborting the rest of the batch.
+func trustServiceWarning(image string, trustErr error) types.ServiceWarning {
+	return types.ServiceWarning{
+		Code:    types.TrustResolutionFailed,
+		Image:   image,
+		Message: trustErr.Error(),
+		Err:     trustErr,
+	}
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+)
+
+// unauthorizedTestError is a stand-in for the errdefs-wrapped error a real
+// transport would return on a 401/403 from the registry; it implements
+// errdefs.ErrUnauthorized the same way that wrapped error would.
+type unauthorizedTestError struct {
+	cause error
+}
+
+func (e *unauthorizedTestError) Error() string { return e.cause.Error() }
+func (e *unauthorizedTestError) Unwrap() error { return e.cause }
+func (e *unauthorizedTestError) Unauthorized() {}
+
+func TestDigestServiceWarningClassifiesUnauthorized(t *testing.T) {
+	unauthorized := &unauthorizedTestError{cause: fmt.Errorf("denied")}
+	warning := digestServiceWarning("alpine:latest", unauthorized)
+	if warning.Code != types.RegistryUnauthorized {
+		t.Fatalf("expected RegistryUnauthorized, got %v", warning.Code)
+	}
+
+	other := fmt.Errorf("registry unreachable")
+	warning = digestServiceWarning("alpine:latest", other)
+	if warning.Code != types.DigestPinFailed {
+		t.Fatalf("expected DigestPinFailed, got %v", warning.Code)
+	}
+}
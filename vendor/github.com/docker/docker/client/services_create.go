@@ -0,0 +1,263 @@
+package client
+
+import (
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+// defaultMaxConcurrentRegistryQueries bounds how many DistributionInspect
+// calls ServicesCreate makes in parallel when ServicesCreateOptions leaves
+// MaxConcurrentRegistryQueries unset.
+const defaultMaxConcurrentRegistryQueries = 8
+
+// ServicesCreateOptions holds the parameters for a ServicesCreate call. It
+// mirrors ServiceCreateOptions but applies to the whole batch.
+type ServicesCreateOptions struct {
+	EncodedRegistryAuth string
+	QueryRegistry       bool
+
+	// TrustedResolution mirrors ServiceCreateOptions.TrustedResolution:
+	// every service's image is resolved through the Client's TrustResolver
+	// instead of the registry. Takes priority over QueryRegistry, the same
+	// way it does in ServiceCreate.
+	TrustedResolution bool
+
+	// MaxConcurrentRegistryQueries bounds how many DistributionInspect (or,
+	// with TrustedResolution, trust server) calls are in flight at once.
+	// Zero or negative falls back to defaultMaxConcurrentRegistryQueries.
+	MaxConcurrentRegistryQueries int
+}
+
+// ServiceCreateResult is one service's outcome from a ServicesCreate call.
+type ServiceCreateResult struct {
+	Spec     swarm.ServiceSpec
+	Response types.ServiceCreateResponse
+	Err      error
+}
+
+// ServicesCreate creates many services, resolving each one's registry
+// digest and platform information up front. When options.QueryRegistry is
+// set, the resolver lookups are performed concurrently, bounded
+// by MaxConcurrentRegistryQueries, and deduplicated across the batch: a
+// compose stack that references the same handful of images across dozens
+// of services only hits the registry once per distinct image rather than
+// once per service.
+func (cli *Client) ServicesCreate(ctx context.Context, specs []swarm.ServiceSpec, options ServicesCreateOptions) ([]ServiceCreateResult, error) {
+	// Capture each spec's image before resolution mutates it in place: the
+	// error maps below are keyed by this original, pre-pin reference, and
+	// specs[i].TaskTemplate.ContainerSpec.Image will usually have changed
+	// to a canonical digest reference by the time we look the key back up.
+	originalImages := make([]string, len(specs))
+	for i := range specs {
+		originalImages[i] = specs[i].TaskTemplate.ContainerSpec.Image
+	}
+
+	var distErrors, platformErrors, trustErrors map[string]error
+	switch {
+	case options.TrustedResolution:
+		trustErrors = cli.resolveBatchTrust(ctx, specs, options)
+	case options.QueryRegistry:
+		distErrors, platformErrors = cli.resolveBatchDigests(ctx, specs, options)
+	}
+
+	results := make([]ServiceCreateResult, len(specs))
+	perServiceOptions := types.ServiceCreateOptions{EncodedRegistryAuth: options.EncodedRegistryAuth}
+	for i := range specs {
+		image := originalImages[i]
+		// A trust failure must fail closed exactly like ServiceCreate's own
+		// TrustedResolution branch does: create nothing for this service
+		// and surface the error, rather than creating it with the original,
+		// unverified tag and merely warning about it.
+		if trustErr := trustErrors[image]; trustErr != nil {
+			results[i] = ServiceCreateResult{
+				Spec:     specs[i],
+				Response: types.ServiceCreateResponse{StructuredWarnings: []types.ServiceWarning{trustServiceWarning(image, trustErr)}},
+				Err:      trustErr,
+			}
+			continue
+		}
+		resp, err := cli.ServiceCreate(ctx, specs[i], perServiceOptions)
+		if err == nil {
+			if distErr := distErrors[image]; distErr != nil {
+				resp.Warnings = append(resp.Warnings, digestWarning(image))
+				resp.StructuredWarnings = append(resp.StructuredWarnings, digestServiceWarning(image, distErr))
+			}
+			if platformErr := platformErrors[image]; platformErr != nil {
+				resp.Warnings = append(resp.Warnings, platformErr.Error())
+				resp.StructuredWarnings = append(resp.StructuredWarnings, platformServiceWarning(image, platformErr))
+			}
+		}
+		results[i] = ServiceCreateResult{Spec: specs[i], Response: resp, Err: err}
+	}
+	return results, nil
+}
+
+// resolveBatchTrust pins every spec's image to a Notary-signed digest using
+// cli.trustResolver, sharing one resolution per distinct image across the
+// batch the same way resolveBatchDigests dedupes registry lookups. It
+// returns the trust resolution error, if any, keyed by the original
+// (pre-pin) image reference, so ServicesCreate can surface a per-service
+// TrustResolutionFailed warning without aborting the rest of the batch.
+func (cli *Client) resolveBatchTrust(ctx context.Context, specs []swarm.ServiceSpec, options ServicesCreateOptions) map[string]error {
+	indicesByImage := make(map[string][]int, len(specs))
+	for i, spec := range specs {
+		image := spec.TaskTemplate.ContainerSpec.Image
+		indicesByImage[image] = append(indicesByImage[image], i)
+	}
+
+	images := make([]string, 0, len(indicesByImage))
+	for image := range indicesByImage {
+		images = append(images, image)
+	}
+
+	workers := options.MaxConcurrentRegistryQueries
+	if workers <= 0 {
+		workers = defaultMaxConcurrentRegistryQueries
+	}
+	if workers > len(images) {
+		workers = len(images)
+	}
+
+	type resolution struct {
+		image string
+		img   string
+		err   error
+	}
+
+	jobs := make(chan string)
+	resolutions := make(chan resolution)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for image := range jobs {
+				img, err := resolveTrustedImage(ctx, cli.trustResolver, image)
+				resolutions <- resolution{image: image, img: img, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, image := range images {
+			jobs <- image
+		}
+		close(jobs)
+	}()
+	go func() {
+		wg.Wait()
+		close(resolutions)
+	}()
+
+	cache := make(map[string]resolution, len(images))
+	for r := range resolutions {
+		cache[r.image] = r
+	}
+
+	trustErrors := make(map[string]error, len(images))
+	for image, indices := range indicesByImage {
+		r := cache[image]
+		if r.err != nil {
+			trustErrors[image] = r.err
+			continue
+		}
+		for _, i := range indices {
+			specs[i].TaskTemplate.ContainerSpec.Image = r.img
+		}
+	}
+	return trustErrors
+}
+
+// resolveBatchDigests pins every spec's image to a registry digest and
+// updates its placement platforms in place, sharing one ImageResolver.Resolve
+// call per distinct image across the batch. It returns the resolution and
+// platform-intersection errors, if any, keyed by the original (pre-pin)
+// image reference, so callers can still surface a per-service warning the
+// way ServiceCreate does.
+func (cli *Client) resolveBatchDigests(ctx context.Context, specs []swarm.ServiceSpec, options ServicesCreateOptions) (distErrors, platformErrors map[string]error) {
+	indicesByImage := make(map[string][]int, len(specs))
+	for i, spec := range specs {
+		image := spec.TaskTemplate.ContainerSpec.Image
+		indicesByImage[image] = append(indicesByImage[image], i)
+	}
+
+	images := make([]string, 0, len(indicesByImage))
+	for image := range indicesByImage {
+		images = append(images, image)
+	}
+
+	workers := options.MaxConcurrentRegistryQueries
+	if workers <= 0 {
+		workers = defaultMaxConcurrentRegistryQueries
+	}
+	if workers > len(images) {
+		workers = len(images)
+	}
+
+	type resolution struct {
+		image     string
+		digest    digest.Digest
+		platforms []ocispec.Platform
+		err       error
+	}
+
+	jobs := make(chan string)
+	resolutions := make(chan resolution)
+
+	resolver := cli.imageResolverFor()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for image := range jobs {
+				dgst, platforms, err := resolver.Resolve(ctx, image, options.EncodedRegistryAuth)
+				resolutions <- resolution{image: image, digest: dgst, platforms: platforms, err: err}
+			}
+		}()
+	}
+	go func() {
+		for _, image := range images {
+			jobs <- image
+		}
+		close(jobs)
+	}()
+	go func() {
+		wg.Wait()
+		close(resolutions)
+	}()
+
+	// image -> resolution cache, shared across every spec that references
+	// the same image.
+	cache := make(map[string]resolution, len(images))
+	for r := range resolutions {
+		cache[r.image] = r
+	}
+
+	distErrors = make(map[string]error, len(images))
+	platformErrors = make(map[string]error, len(images))
+	for image, indices := range indicesByImage {
+		r := cache[image]
+		if r.err != nil {
+			distErrors[image] = r.err
+			continue
+		}
+		for _, i := range indices {
+			spec := &specs[i]
+			if img := imageWithDigestString(image, r.digest); img != "" {
+				spec.TaskTemplate.ContainerSpec.Image = img
+			}
+			placement, err := updateServicePlatforms(spec.TaskTemplate.Placement, platformsFromOCI(r.platforms))
+			spec.TaskTemplate.Placement = placement
+			if err != nil {
+				platformErrors[image] = err
+			}
+		}
+	}
+	return distErrors, platformErrors
+}
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+// countingResolver records how many times Resolve was called per image and
+// the highest number of concurrent Resolve calls it observed, so tests can
+// assert on both deduplication and the worker-pool concurrency bound.
+type countingResolver struct {
+	mu    sync.Mutex
+	calls map[string]int
+
+	inFlight    int32
+	maxInFlight int32
+}
+
+func newCountingResolver() *countingResolver {
+	return &countingResolver{calls: make(map[string]int)}
+}
+
+func (r *countingResolver) Resolve(ctx context.Context, ref, encodedAuth string) (digest.Digest, []ocispec.Platform, error) {
+	cur := atomic.AddInt32(&r.inFlight, 1)
+	defer atomic.AddInt32(&r.inFlight, -1)
+	for {
+		max := atomic.LoadInt32(&r.maxInFlight)
+		if cur <= max {
+			break
+		}
+		if atomic.CompareAndSwapInt32(&r.maxInFlight, max, cur) {
+			break
+		}
+	}
+	// Give other goroutines a chance to overlap with this call.
+	time.Sleep(5 * time.Millisecond)
+
+	r.mu.Lock()
+	r.calls[ref]++
+	r.mu.Unlock()
+
+	return digest.Digest("sha256:" + strings.Repeat("a", 64)), nil, nil
+}
+
+func specWithImage(image string) swarm.ServiceSpec {
+	spec := swarm.ServiceSpec{}
+	spec.TaskTemplate.ContainerSpec.Image = image
+	return spec
+}
+
+func TestResolveBatchDigestsDedupesPerImage(t *testing.T) {
+	resolver := newCountingResolver()
+	cli := &Client{imageResolver: resolver}
+
+	specs := []swarm.ServiceSpec{
+		specWithImage("alpine:latest"),
+		specWithImage("alpine:latest"),
+		specWithImage("alpine:latest"),
+		specWithImage("nginx:stable"),
+	}
+
+	distErrors, platformErrors := cli.resolveBatchDigests(context.Background(), specs, ServicesCreateOptions{})
+	if len(distErrors) != 0 || len(platformErrors) != 0 {
+		t.Fatalf("unexpected errors: dist=%v platform=%v", distErrors, platformErrors)
+	}
+	if got := resolver.calls["alpine:latest"]; got != 1 {
+		t.Fatalf("expected alpine:latest to be resolved once for 3 services, got %d calls", got)
+	}
+	if got := resolver.calls["nginx:stable"]; got != 1 {
+		t.Fatalf("expected nginx:stable to be resolved once, got %d calls", got)
+	}
+}
+
+// countingTrustResolver records how many times ResolveTrustedDigest was
+// called per image, and fails for any image in failFor.
+type countingTrustResolver struct {
+	mu      sync.Mutex
+	calls   map[string]int
+	failFor map[string]bool
+}
+
+func newCountingTrustResolver(failFor ...string) *countingTrustResolver {
+	fail := make(map[string]bool, len(failFor))
+	for _, image := range failFor {
+		fail[image] = true
+	}
+	return &countingTrustResolver{calls: make(map[string]int), failFor: fail}
+}
+
+func (r *countingTrustResolver) ResolveTrustedDigest(ctx context.Context, ref reference.Named) (digest.Digest, error) {
+	// Key by the familiar "name:tag" form the tests use ("alpine:latest");
+	// ref.Name() drops the tag and is fully qualified.
+	image := reference.FamiliarString(ref)
+	r.mu.Lock()
+	r.calls[image]++
+	r.mu.Unlock()
+
+	if r.failFor[image] {
+		return "", fmt.Errorf("no signed target for %s", image)
+	}
+	return digest.Digest("sha256:" + strings.Repeat("b", 64)), nil
+}
+
+func TestResolveBatchTrustDedupesPerImage(t *testing.T) {
+	resolver := newCountingTrustResolver()
+	cli := &Client{trustResolver: resolver}
+
+	specs := []swarm.ServiceSpec{
+		specWithImage("alpine:latest"),
+		specWithImage("alpine:latest"),
+		specWithImage("alpine:latest"),
+		specWithImage("nginx:stable"),
+	}
+
+	trustErrors := cli.resolveBatchTrust(context.Background(), specs, ServicesCreateOptions{})
+	if len(trustErrors) != 0 {
+		t.Fatalf("unexpected trust errors: %v", trustErrors)
+	}
+	if got := resolver.calls["alpine:latest"]; got != 1 {
+		t.Fatalf("expected alpine:latest to be resolved once for 3 services, got %d calls", got)
+	}
+	for i, spec := range specs[:3] {
+		if spec.TaskTemplate.ContainerSpec.Image == "alpine:latest" {
+			t.Fatalf("spec %d: image was not pinned to a trusted digest", i)
+		}
+	}
+}
+
+func TestResolveBatchTrustFailureIsNotCreated(t *testing.T) {
+	resolver := newCountingTrustResolver("bad:latest")
+	cli := &Client{trustResolver: resolver}
+
+	specs := []swarm.ServiceSpec{
+		specWithImage("bad:latest"),
+		specWithImage("alpine:latest"),
+	}
+
+	trustErrors := cli.resolveBatchTrust(context.Background(), specs, ServicesCreateOptions{})
+	if trustErrors["bad:latest"] == nil {
+		t.Fatal("expected a trust error for bad:latest")
+	}
+	if trustErrors["alpine:latest"] != nil {
+		t.Fatalf("unexpected trust error for alpine:latest: %v", trustErrors["alpine:latest"])
+	}
+	// ServicesCreate keys its fail-closed check off this same map: an image
+	// with a recorded trust error must never have had its spec mutated into
+	// a pinned reference, because the digest it would be pinned to was
+	// never verified.
+	if specs[0].TaskTemplate.ContainerSpec.Image != "bad:latest" {
+		t.Fatalf("expected bad:latest's spec to be left untouched, got %q", specs[0].TaskTemplate.ContainerSpec.Image)
+	}
+}
+
+func TestResolveBatchDigestsBoundsConcurrency(t *testing.T) {
+	resolver := newCountingResolver()
+	cli := &Client{imageResolver: resolver}
+
+	var specs []swarm.ServiceSpec
+	for i := 0; i < 10; i++ {
+		specs = append(specs, specWithImage(fmt.Sprintf("image%d:latest", i)))
+	}
+
+	const maxConcurrent = 2
+	cli.resolveBatchDigests(context.Background(), specs, ServicesCreateOptions{MaxConcurrentRegistryQueries: maxConcurrent})
+
+	if resolver.maxInFlight > maxConcurrent {
+		t.Fatalf("observed %d concurrent Resolve calls, want at most %d", resolver.maxInFlight, maxConcurrent)
+	}
+}
@@ -0,0 +1,12 @@
+package errdefs
+
+// ErrUnauthorized signals that the user is not authorized to perform an
+// operation.
+//
+// This vendor copy only carries the classification used elsewhere in this
+// tree (the client package's registry-unauthorized check); the real
+// package defines a full set of these tag interfaces (ErrNotFound,
+// ErrInvalidParameter, ErrConflict, and so on) alongside it.
+type ErrUnauthorized interface {
+	Unauthorized()
+}
@@ -0,0 +1,33 @@
+package errdefs
+
+// causer is implemented by errors produced with pkg/errors-style wrapping;
+// getImplementer follows it, and the standard Unwrap chain, down to the
+// first error that actually implements one of this package's
+// classification interfaces.
+type causer interface {
+	Cause() error
+}
+
+type unwrapper interface {
+	Unwrap() error
+}
+
+func getImplementer(err error) error {
+	switch e := err.(type) {
+	case ErrUnauthorized:
+		return e
+	case causer:
+		return getImplementer(e.Cause())
+	case unwrapper:
+		return getImplementer(e.Unwrap())
+	default:
+		return err
+	}
+}
+
+// IsUnauthorized returns true if the passed in error denotes a request not
+// being authorized to perform an action.
+func IsUnauthorized(err error) bool {
+	_, ok := getImplementer(err).(ErrUnauthorized)
+	return ok
+}